@@ -0,0 +1,97 @@
+package rollingfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Archiver controls what happens to a log file once rollingFile has
+// renamed it out of the way during a roll. Register a custom one via
+// WithArchiver to implement retention schemes (e.g. shipping the file to
+// S3 from AfterArchive) without forking this module. The default is
+// ArchiveRenameOnly, which leaves the file exactly where roll() put it.
+type Archiver interface {
+	// ShouldArchive reports whether name should be archived at all.
+	ShouldArchive(name string, info os.FileInfo) bool
+	// NextPath returns the path name's contents should end up at. A
+	// return value equal to name means no further action is needed.
+	NextPath(name string, info os.FileInfo) string
+	// BeforeArchive runs before src is moved to dst; typical uses are
+	// preparing the destination, e.g. creating a subdirectory.
+	BeforeArchive(src, dst string, info os.FileInfo) error
+	// AfterArchive is responsible for getting src's contents to dst (a
+	// plain rename, a compress-then-remove, a network upload, ...) and
+	// for any follow-up once that is done.
+	AfterArchive(src, dst string, info os.FileInfo) error
+}
+
+// Built-in archivers for use with WithArchiver.
+var (
+	// ArchiveRenameOnly leaves a rolled file exactly where roll() already
+	// renamed it to. This is the default.
+	ArchiveRenameOnly Archiver = renameOnlyArchiver{}
+	// ArchiveGzipRemove gzip-compresses a rolled file to name+".gz" and
+	// removes the uncompressed original.
+	//
+	// Unlike WithCompression, which compresses in a background goroutine
+	// specifically so it never blocks Fire, all Archiver methods
+	// (including this one's AfterArchive) run synchronously inside roll(),
+	// under rollLock — the same place a plain rename happens for
+	// ArchiveRenameOnly. A large rolled file will make the Fire call that
+	// triggered the roll block until compression finishes. Registering a
+	// custom Archiver is what gives up WithCompression's non-blocking
+	// guarantee; if you need both compression and a non-blocking Fire, use
+	// WithCompression instead of this.
+	ArchiveGzipRemove Archiver = gzipArchiver{}
+	// ArchiveMoveToMonthSubdir moves a rolled file into a "YYYY-MM"
+	// subdirectory of its own directory, keyed off its ModTime.
+	ArchiveMoveToMonthSubdir Archiver = subdirArchiver{}
+)
+
+type renameOnlyArchiver struct{}
+
+func (renameOnlyArchiver) ShouldArchive(string, os.FileInfo) bool { return true }
+func (renameOnlyArchiver) NextPath(name string, _ os.FileInfo) string {
+	return name
+}
+func (renameOnlyArchiver) BeforeArchive(string, string, os.FileInfo) error { return nil }
+func (renameOnlyArchiver) AfterArchive(string, string, os.FileInfo) error  { return nil }
+
+// gzipArchiver compresses with the same compressRollFile WithCompression
+// uses, but expressed as an Archiver so it can be combined with a custom
+// BeforeArchive/AfterArchive for additional side effects. Unlike
+// WithCompression, it runs synchronously inside roll() — see
+// ArchiveGzipRemove.
+type gzipArchiver struct{}
+
+func (gzipArchiver) ShouldArchive(string, os.FileInfo) bool { return true }
+
+func (gzipArchiver) NextPath(name string, _ os.FileInfo) string {
+	return name + compressedFileSuffix
+}
+
+func (gzipArchiver) BeforeArchive(string, string, os.FileInfo) error { return nil }
+
+func (gzipArchiver) AfterArchive(src, _ string, _ os.FileInfo) error {
+	return compressRollFile(src, CompressionGzip)
+}
+
+// subdirArchiver moves a rolled file into a dated subdirectory, which is
+// handy for keeping a roll directory from accumulating thousands of
+// entries.
+type subdirArchiver struct{}
+
+func (subdirArchiver) ShouldArchive(string, os.FileInfo) bool { return true }
+
+func (subdirArchiver) NextPath(name string, info os.FileInfo) string {
+	dir, base := filepath.Split(name)
+	return filepath.Join(dir, info.ModTime().Format("2006-01"), base)
+}
+
+func (subdirArchiver) BeforeArchive(_, dst string, _ os.FileInfo) error {
+	return os.MkdirAll(filepath.Dir(dst), defaultDirectoryPermissions)
+}
+
+func (subdirArchiver) AfterArchive(src, dst string, _ os.FileInfo) error {
+	return os.Rename(src, dst)
+}
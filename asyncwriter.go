@@ -0,0 +1,132 @@
+package rollingfile
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// DropPolicy controls what an AsynchronousWriter does once its buffered
+// queue is full.
+type DropPolicy int
+
+const (
+	// DropPolicyBlock blocks Write until the queue has room, trading
+	// write latency for never losing a log line.
+	DropPolicyBlock DropPolicy = iota
+	// DropPolicyDropOldest discards the oldest queued line to make room
+	// for the new one, trading completeness for bounded latency. Under
+	// concurrent writers, another goroutine can refill the slot freed by
+	// the drop before this Write's own enqueue runs; when that happens,
+	// this Write drops its own new line instead, rather than block or
+	// retry.
+	DropPolicyDropOldest
+)
+
+// AsynchronousWriter decouples log emission from disk I/O: Write copies
+// its argument into a pooled buffer and hands it to a background
+// goroutine that performs the actual write to out, so callers of Write
+// (i.e. hookWriter) do not wait for the write to out itself. Under
+// DropPolicyBlock, Write still waits for room once the queue is full.
+type AsynchronousWriter struct {
+	out    io.Writer
+	queue  chan []byte
+	policy DropPolicy
+	pool   sync.Pool
+	errors chan error
+	closed int32
+	done   chan struct{}
+
+	// closeMu pairs with closed/done to stop Write from ever sending on
+	// queue after Close has closed it. Write holds it for a read (so
+	// concurrent writers don't block each other); Close takes it
+	// exclusively, which blocks until every in-flight Write has returned,
+	// before closing queue.
+	closeMu sync.RWMutex
+}
+
+func newAsynchronousWriter(out io.Writer, size int, policy DropPolicy) *AsynchronousWriter {
+	w := &AsynchronousWriter{
+		out:    out,
+		queue:  make(chan []byte, size),
+		policy: policy,
+		errors: make(chan error, size),
+		done:   make(chan struct{}),
+		pool: sync.Pool{
+			New: func() interface{} { return make([]byte, 0, 256) },
+		},
+	}
+	go w.run()
+	return w
+}
+
+// run drains the queue to out until it is closed, so Close can block
+// until every already-queued line has actually been written.
+func (w *AsynchronousWriter) run() {
+	defer close(w.done)
+	for buf := range w.queue {
+		if _, err := w.out.Write(buf); err != nil {
+			select {
+			case w.errors <- err:
+			default:
+			}
+		}
+		w.pool.Put(buf[:0]) //nolint:staticcheck // buf is not retained after this point.
+	}
+}
+
+// Write implements io.Writer. p is copied before being queued, so the
+// caller is free to reuse it as soon as Write returns.
+func (w *AsynchronousWriter) Write(p []byte) (int, error) {
+	w.closeMu.RLock()
+	defer w.closeMu.RUnlock()
+
+	if atomic.LoadInt32(&w.closed) != 0 {
+		return 0, io.ErrClosedPipe
+	}
+
+	buf := w.pool.Get().([]byte)
+	buf = append(buf[:0], p...)
+
+	switch w.policy {
+	case DropPolicyDropOldest:
+		select {
+		case w.queue <- buf:
+		default:
+			select {
+			case dropped := <-w.queue:
+				w.pool.Put(dropped[:0]) //nolint:staticcheck // dropped is not retained after this point.
+			default:
+			}
+			select {
+			case w.queue <- buf:
+			default:
+			}
+		}
+	default:
+		w.queue <- buf
+	}
+
+	return len(p), nil
+}
+
+// Errors returns the channel write failures are reported on.
+func (w *AsynchronousWriter) Errors() <-chan error {
+	return w.errors
+}
+
+// Close stops accepting new writes, drains whatever is already queued to
+// out, and only then returns. It is idempotent and safe to call more than
+// once.
+func (w *AsynchronousWriter) Close() error {
+	w.closeMu.Lock()
+	defer w.closeMu.Unlock()
+
+	if !atomic.CompareAndSwapInt32(&w.closed, 0, 1) {
+		return nil
+	}
+	close(w.queue)
+	<-w.done
+	close(w.errors)
+	return nil
+}
@@ -0,0 +1,134 @@
+package rollingfile
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// TestAsynchronousWriterCloseWhileWriting races Write against Close on the
+// AsynchronousWriter directly, with no rollLock serializing the two. Before
+// closeMu this panicked with "send on closed channel"; the test passes as
+// long as it doesn't.
+func TestAsynchronousWriterCloseWhileWriting(t *testing.T) {
+	var buf bytes.Buffer
+	w := newAsynchronousWriter(&buf, 4, DropPolicyBlock)
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			w.Write([]byte("x"))
+		}()
+	}
+
+	close(start)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+	wg.Wait()
+}
+
+func TestAsynchronousWriterCloseIsIdempotent(t *testing.T) {
+	var buf bytes.Buffer
+	w := newAsynchronousWriter(&buf, 4, DropPolicyBlock)
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("first Close: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("second Close should be a no-op, got: %s", err)
+	}
+}
+
+// blockingWriter signals ready the first time Write is called, then blocks
+// until unblock is closed, letting a test pin run()'s goroutine mid-write
+// so it can fill the queue deterministically behind it.
+type blockingWriter struct {
+	ready    chan struct{}
+	unblock  chan struct{}
+	readyOne sync.Once
+
+	mu     sync.Mutex
+	writes []string
+}
+
+func (b *blockingWriter) Write(p []byte) (int, error) {
+	b.readyOne.Do(func() { close(b.ready) })
+	<-b.unblock
+
+	b.mu.Lock()
+	b.writes = append(b.writes, string(p))
+	b.mu.Unlock()
+	return len(p), nil
+}
+
+// TestAsynchronousWriterDropOldestDropsOldestNotNewest exercises
+// DropPolicyDropOldest under a full queue and asserts on which lines
+// actually reach out, something no test in the series covered despite the
+// policy being a documented requirement.
+func TestAsynchronousWriterDropOldestDropsOldestNotNewest(t *testing.T) {
+	bw := &blockingWriter{ready: make(chan struct{}), unblock: make(chan struct{})}
+	w := newAsynchronousWriter(bw, 2, DropPolicyDropOldest)
+
+	if _, err := w.Write([]byte("1")); err != nil {
+		t.Fatalf("Write(1): %s", err)
+	}
+	// Wait for run() to have dequeued "1" into the blocked call below, so
+	// the queue (capacity 2) is empty again for the writes that follow.
+	<-bw.ready
+
+	for _, line := range []string{"2", "3", "4"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write(%s): %s", line, err)
+		}
+	}
+
+	close(bw.unblock)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	want := []string{"1", "3", "4"}
+	bw.mu.Lock()
+	got := append([]string{}, bw.writes...)
+	bw.mu.Unlock()
+	if len(got) != len(want) {
+		t.Fatalf("writes = %v, want %v", got, want)
+	}
+	for i, w := range want {
+		if got[i] != w {
+			t.Fatalf("writes = %v, want %v", got, want)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("boom")
+}
+
+func TestAsynchronousWriterReportsWriteErrors(t *testing.T) {
+	w := newAsynchronousWriter(failingWriter{}, 4, DropPolicyBlock)
+
+	if _, err := w.Write([]byte("x")); err != nil {
+		t.Fatalf("Write: %s", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %s", err)
+	}
+
+	select {
+	case err := <-w.Errors():
+		if err == nil {
+			t.Fatal("expected a non-nil write error")
+		}
+	default:
+		t.Fatal("expected the failed write to be reported on Errors()")
+	}
+}
@@ -0,0 +1,72 @@
+package rollingfile
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Supported values for WithCompression.
+const (
+	CompressionGzip = "gzip"
+	// CompressionZstd is reserved for future support.
+	CompressionZstd = "zstd"
+)
+
+const (
+	compressedFileSuffix = ".gz"
+	compressionTmpSuffix = ".tmp"
+)
+
+// compressRollFile compresses the rolled-out file at path in place,
+// producing path+compressedFileSuffix. It writes to a temporary file
+// first and renames it into place on success, so a reader never observes
+// a partially written archive. The original file is removed once the
+// archive exists.
+func compressRollFile(path, algo string) error {
+	switch algo {
+	case CompressionGzip:
+		return compressGzip(path)
+	default:
+		return fmt.Errorf("logrus rolling file hook: unsupported compression algorithm %q", algo)
+	}
+}
+
+func compressGzip(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmpPath := path + compressedFileSuffix + compressionTmpSuffix
+	dst, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, defaultFilePermissions)
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(dst)
+	if _, err = io.Copy(gw, src); err != nil {
+		gw.Close()
+		dst.Close()
+		tryRemoveFile(tmpPath)
+		return err
+	}
+	if err = gw.Close(); err != nil {
+		dst.Close()
+		tryRemoveFile(tmpPath)
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		tryRemoveFile(tmpPath)
+		return err
+	}
+
+	if err = os.Rename(tmpPath, path+compressedFileSuffix); err != nil {
+		tryRemoveFile(tmpPath)
+		return err
+	}
+
+	return tryRemoveFile(path)
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 // File and directory permitions.
@@ -95,3 +96,37 @@ func tryRemoveFile(filePath string) (err error) {
 	}
 	return
 }
+
+// activeFilesMu guards activeFiles.
+var activeFilesMu sync.Mutex
+
+// activeFiles ref-counts the absolute path of every hook's active file,
+// across the whole process. WithFileNameGlob lets several hooks share a
+// directory and a glob pattern (e.g. "panic.log" and "audit.log" both
+// matching "*.log"); without this, each hook's age-pruning would only
+// exclude its own active file and could delete another hook's out from
+// under it. registerActiveFile/unregisterActiveFile are called once per
+// hook lifetime, from newRollingFile and Close.
+var activeFiles = make(map[string]int)
+
+func registerActiveFile(path string) {
+	activeFilesMu.Lock()
+	defer activeFilesMu.Unlock()
+	activeFiles[path]++
+}
+
+func unregisterActiveFile(path string) {
+	activeFilesMu.Lock()
+	defer activeFilesMu.Unlock()
+	if activeFiles[path] <= 1 {
+		delete(activeFiles, path)
+		return
+	}
+	activeFiles[path]--
+}
+
+func isActiveFile(path string) bool {
+	activeFilesMu.Lock()
+	defer activeFilesMu.Unlock()
+	return activeFiles[path] > 0
+}
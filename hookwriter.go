@@ -0,0 +1,28 @@
+package rollingfile
+
+// hookWriter is the single, stable io.Writer returned by rollingFile.Writer
+// that a caller points logger.Out at via logger.SetOutput(hook.Writer()),
+// once, outside of Fire. Its Write locks rollLock for the full duration of
+// the write — the same lock roll() holds — and resolves rf.out() fresh on
+// every call. That means a concurrent roll() can never race a write
+// through a stale logger.Out, and recovering the active file after
+// external rotation (see watchForExternalRotation) needs no code to reach
+// back into *logrus.Logger and reassign Out: the next write simply
+// observes the reopened file.
+//
+// Fire must never assign logger.Out itself: logrus's entry.fireHooks
+// releases Logger.mu before calling Fire, while entry.write reads
+// Logger.Out under that same mutex, so any write to it from inside Fire is
+// an unsynchronized, concurrent access to a field logrus itself expects to
+// own. Routing through this stable writer instead means logger.Out is
+// only ever assigned once, by the caller, long before any concurrent Fire
+// call can observe it.
+type hookWriter struct {
+	rf *rollingFile
+}
+
+func (hw *hookWriter) Write(p []byte) (int, error) {
+	hw.rf.rollLock.Lock()
+	defer hw.rf.rollLock.Unlock()
+	return hw.rf.out().Write(p)
+}
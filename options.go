@@ -0,0 +1,86 @@
+package rollingfile
+
+import "time"
+
+// Option configures optional behavior shared by the rolling file hooks.
+// Options are applied in order when a hook is constructed.
+type Option func(*rollingFile)
+
+// WithCompression enables background compression of rolled-out history
+// files using the given algorithm. Compression happens asynchronously
+// after a roll, so it never blocks Fire. Currently only "gzip" is
+// supported; passing an unsupported algorithm only surfaces an error once
+// a roll actually happens. An empty algo (the default) disables
+// compression.
+//
+// maxRolls may be transiently exceeded by a file still being compressed:
+// deleteOldRolls leaves it alone rather than delete it out from under the
+// compression, and enforces maxRolls again as soon as that compression
+// finishes.
+//
+// Mutually exclusive with WithArchiver: once a custom Archiver has been
+// registered, it alone decides what happens to a rolled file (see
+// ArchiveGzipRemove for the Archiver equivalent of this option), and
+// WithCompression's background goroutine is not started.
+func WithCompression(algo string) Option {
+	return func(rf *rollingFile) {
+		rf.compressionAlgo = algo
+	}
+}
+
+// WithMaxAge prunes history files older than d, in addition to the
+// existing maxRolls count-based limit. Pruning runs once at hook
+// construction and again after every roll.
+func WithMaxAge(d time.Duration) Option {
+	return func(rf *rollingFile) {
+		rf.maxAge = d
+	}
+}
+
+// WithFileNameGlob makes age-based pruning (see WithMaxAge) match
+// candidate files by pattern instead of this hook's own file name, so
+// several related log families sharing a directory (e.g. "panic-*.log"
+// and "audit-*.log") can be cleaned up by a single hook.
+func WithFileNameGlob(pattern string) Option {
+	return func(rf *rollingFile) {
+		rf.fileNameGlob = pattern
+	}
+}
+
+// WithAsyncBuffer makes writes through Writer hand log lines off to a
+// bounded buffered channel consumed by a background goroutine (an
+// AsynchronousWriter),
+// instead of writing to the current file synchronously. size is the
+// channel capacity; policy controls what happens once it fills.
+func WithAsyncBuffer(size int, policy DropPolicy) Option {
+	return func(rf *rollingFile) {
+		rf.asyncBufferSize = size
+		rf.asyncDropPolicy = policy
+	}
+}
+
+// WithArchiver overrides what happens to a file once it has been renamed
+// out of the way during a roll. The default is ArchiveRenameOnly.
+//
+// Registering one takes over from, and disables, WithCompression's
+// built-in background gzip goroutine — use ArchiveGzipRemove for
+// equivalent behavior expressed as an Archiver if you also need custom
+// Before/AfterArchive side effects. Note that, unlike WithCompression, an
+// Archiver's methods all run synchronously inside roll(), blocking the
+// Fire call that triggered it; see ArchiveGzipRemove.
+func WithArchiver(a Archiver) Option {
+	return func(rf *rollingFile) {
+		rf.archiver = a
+	}
+}
+
+// WithCurrentSymlink maintains linkPath as a stable symlink pointing at
+// whatever the active roll file currently is, updated atomically on every
+// roll. It also starts a background watcher that reopens the active file
+// if something external (e.g. logrotate) renames or removes it out from
+// under the hook.
+func WithCurrentSymlink(linkPath string) Option {
+	return func(rf *rollingFile) {
+		rf.currentSymlink = linkPath
+	}
+}
@@ -1,11 +1,26 @@
+// Package rollingfile implements logrus hooks that roll a log file by size
+// or by time, with optional compression, age-based pruning, an
+// asynchronous write pipeline, and recovery from external rotation.
+//
+// Most of the package's bugs have been concurrency bugs: Fire (and the
+// roll() it calls) runs under rollLock, but the background compression
+// goroutine, the AsynchronousWriter pipeline, and the external-rotation
+// watcher all touch
+// the same state from outside that call stack. `go test ./...` alone will
+// not catch a reintroduced race here — run `go test -race ./...` before
+// trusting a change to any of the above.
 package rollingfile
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
 )
 
 // Common constants
@@ -46,12 +61,31 @@ type rollingFile struct {
 	rollingType     rollingType // Rolling mode (Files roll by size/date/...)
 	maxRolls        int
 
+	compressionAlgo string        // Non-empty to compress rolled-out files in the background.
+	compressingMu   sync.Mutex    // Guards compressing.
+	compressing     map[string]bool // History file names with a background compression in flight.
+	maxAge          time.Duration // Non-zero to additionally prune history files by age.
+	fileNameGlob    string        // Non-empty overrides the file name used to find age-pruning candidates.
+
+	asyncBufferSize int             // Non-zero enables the AsynchronousWriter write pipeline.
+	asyncDropPolicy DropPolicy      // What to do once the async buffer fills.
+	asyncWriter     *AsynchronousWriter
+
+	archiver Archiver // What to do with a file once it has been rolled. Defaults to ArchiveRenameOnly.
+
+	currentSymlink string // Non-empty to maintain a symlink to the active file and watch for external rotation.
+	fsWatcher      *fsnotify.Watcher
+
+	activeFilePath string // Absolute path of fileName, registered in activeFiles for the lifetime of the hook.
+
+	writer *hookWriter // The stable io.Writer the caller points logger.Out at via Writer. Created lazily.
+
 	self            rollerVirtual // Used for virtual calls
 
 	rollLock        sync.Mutex
 }
 
-func newRollingFile(fpath string, rtype rollingType, maxr int) (*rollingFile, error) {
+func newRollingFile(fpath string, rtype rollingType, maxr int, opts ...Option) (*rollingFile, error) {
 	rf := new(rollingFile)
 	rf.currentDirPath, rf.fileName = filepath.Split(fpath)
 	if len(rf.currentDirPath) == 0{
@@ -60,11 +94,33 @@ func newRollingFile(fpath string, rtype rollingType, maxr int) (*rollingFile, er
 
 	rf.rollingType = rtype
 	rf.maxRolls = maxr
+	rf.archiver = ArchiveRenameOnly
+
+	for _, opt := range opts {
+		opt(rf)
+	}
+
+	absPath, err := filepath.Abs(filepath.Join(rf.currentDirPath, rf.fileName))
+	if err != nil {
+		return nil, err
+	}
+	rf.activeFilePath = absPath
+	registerActiveFile(rf.activeFilePath)
+
 	return rf, nil
 }
 
 
 func(rf *rollingFile) roll() error {
+	// Drain and stop the async pipeline before closing the file it writes
+	// to, so no buffered line is lost.
+	if rf.asyncWriter != nil {
+		if err := rf.asyncWriter.Close(); err != nil {
+			return err
+		}
+		rf.asyncWriter = nil
+	}
+
 	// First, close current file.
 	err := rf.currentFile.Close()
 	if err != nil {
@@ -104,6 +160,19 @@ func(rf *rollingFile) roll() error {
 		return err
 	}
 
+	// Compression runs in the background so it never blocks Fire. Only
+	// when no custom Archiver has been registered: a custom archiver
+	// (e.g. ArchiveGzipRemove) owns what happens to the file from here,
+	// and running both would race two compressGzip calls over the same
+	// path/path.tmp. See WithCompression and WithArchiver.
+	if rf.compressionAlgo != "" && rf.archiver == ArchiveRenameOnly {
+		go rf.compressHistoryFile(newHistoryName)
+	}
+
+	if err = rf.runArchiver(newHistoryName); err != nil {
+		return err
+	}
+
 	// Finally, add the newly added history file to the history archive
 	// and, if after that the archive exceeds the allowed max limit, older rolls
 	// must the removed/archived.
@@ -115,6 +184,10 @@ func(rf *rollingFile) roll() error {
 		}
 	}
 
+	if err = rf.pruneByAge(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -139,12 +212,19 @@ func (rf *rollingFile) getSortedLogHistory() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
+	// rollNameByTrimmed maps a roll name with any compression suffix
+	// stripped (what self.isFileRollNameValid/sortFileRollNamesAsc expect)
+	// back to the real on-disk roll name, so compressed and uncompressed
+	// rolls sort and count together.
+	rollNameByTrimmed := make(map[string]string)
 	var validRollNames []string
 	for _, file := range files {
 		if rf.hasRollName(file) {
 			rname := rf.getFileRollName(file)
-			if rf.self.isFileRollNameValid(rname) {
-				validRollNames = append(validRollNames, rname)
+			trimmed := strings.TrimSuffix(rname, compressedFileSuffix)
+			if rf.self.isFileRollNameValid(trimmed) {
+				validRollNames = append(validRollNames, trimmed)
+				rollNameByTrimmed[trimmed] = rname
 			}
 		}
 	}
@@ -154,7 +234,7 @@ func (rf *rollingFile) getSortedLogHistory() ([]string, error) {
 	}
 	validSortedFiles := make([]string, len(sortedTails))
 	for i, v := range sortedTails {
-		validSortedFiles[i] = rf.createFullFileName(rf.fileName, v)
+		validSortedFiles[i] = rf.createFullFileName(rf.fileName, rollNameByTrimmed[v])
 	}
 	return validSortedFiles, nil
 }
@@ -174,6 +254,14 @@ func (rf *rollingFile) deleteOldRolls(history []string) error {
 	var err error
 	// In all cases (archive files or not) the files should be deleted.
 	for i := 0; i < rollsToDelete; i++ {
+		// A file mid-compression is left alone: deleting it here would
+		// either race compressHistoryFile's read of it, or "resurrect" it
+		// as an uncounted .gz once the goroutine finishes and renames its
+		// .tmp into place. It gets picked up by a later roll's history
+		// scan once compression has finished.
+		if rf.isCompressing(history[i]) {
+			continue
+		}
 		// Try best to delete files without breaking the loop.
 		if err = tryRemoveFile(filepath.Join(rf.currentDirPath, history[i])); err != nil {
 			fmt.Fprintf(os.Stderr, "logrus rolling file hook internal error: %s\n", err)
@@ -185,6 +273,100 @@ func (rf *rollingFile) deleteOldRolls(history []string) error {
 
 
 
+// compressHistoryFile compresses a just-rolled history file in place,
+// logging (rather than returning) any failure since it runs detached
+// from Fire in its own goroutine. It marks the file as in-flight so a
+// concurrent deleteOldRolls (from the next roll) does not delete it out
+// from under the compression, or resurrect it as an uncounted .gz file;
+// see deleteOldRolls. Once compression is done, it revisits maxRolls via
+// catchUpDeleteOldRolls, since deleteOldRolls may have skipped this (or
+// another) file earlier specifically because it was still in flight.
+func (rf *rollingFile) compressHistoryFile(historyName string) {
+	rf.setCompressing(historyName, true)
+
+	path := filepath.Join(rf.currentDirPath, historyName)
+	if err := compressRollFile(path, rf.compressionAlgo); err != nil {
+		fmt.Fprintf(os.Stderr, "logrus rolling file hook internal error: %s\n", err)
+	}
+
+	rf.setCompressing(historyName, false)
+	rf.catchUpDeleteOldRolls()
+}
+
+// catchUpDeleteOldRolls re-evaluates maxRolls after a background
+// compression finishes. deleteOldRolls intentionally skips a file whose
+// compression is still in flight (see isCompressing) rather than delete or
+// double-count it, which can leave maxRolls exceeded for as long as that
+// compression runs; nothing else revisits that skip once rolling
+// quiesces. This closes that gap. Unlike deleteOldRolls's other caller,
+// roll(), nothing already holds rollLock here, so it takes it itself.
+func (rf *rollingFile) catchUpDeleteOldRolls() {
+	rf.rollLock.Lock()
+	defer rf.rollLock.Unlock()
+
+	history, err := rf.getSortedLogHistory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logrus rolling file hook internal error: %s\n", err)
+		return
+	}
+	if err := rf.deleteOldRolls(history); err != nil {
+		fmt.Fprintf(os.Stderr, "logrus rolling file hook internal error: %s\n", err)
+	}
+}
+
+// setCompressing records (or clears) historyName as having a background
+// compression in flight.
+func (rf *rollingFile) setCompressing(historyName string, inFlight bool) {
+	rf.compressingMu.Lock()
+	defer rf.compressingMu.Unlock()
+
+	if inFlight {
+		if rf.compressing == nil {
+			rf.compressing = make(map[string]bool)
+		}
+		rf.compressing[historyName] = true
+		return
+	}
+	delete(rf.compressing, historyName)
+}
+
+// isCompressing reports whether historyName currently has a background
+// compression in flight.
+func (rf *rollingFile) isCompressing(historyName string) bool {
+	rf.compressingMu.Lock()
+	defer rf.compressingMu.Unlock()
+	return rf.compressing[historyName]
+}
+
+// runArchiver runs rf.archiver (see WithArchiver) against a file that was
+// just rolled into history.
+func (rf *rollingFile) runArchiver(historyName string) error {
+	archiver := rf.archiver
+	if archiver == nil {
+		archiver = ArchiveRenameOnly
+	}
+
+	src := filepath.Join(rf.currentDirPath, historyName)
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	if !archiver.ShouldArchive(src, info) {
+		return nil
+	}
+
+	dst := archiver.NextPath(src, info)
+	if dst == src {
+		return nil
+	}
+
+	if err := archiver.BeforeArchive(src, dst, info); err != nil {
+		return err
+	}
+	return archiver.AfterArchive(src, dst, info)
+}
+
 func (rf *rollingFile) createFileAndFolderIfNeeded() error {
 	var err error
 
@@ -213,11 +395,74 @@ func (rf *rollingFile) createFileAndFolderIfNeeded() error {
 	}
 
 	rf.currentFileSize = stat.Size()
+
+	if rf.asyncBufferSize > 0 {
+		rf.asyncWriter = newAsynchronousWriter(rf.currentFile, rf.asyncBufferSize, rf.asyncDropPolicy)
+	}
+
+	if rf.currentSymlink != "" {
+		if err := rf.updateSymlink(); err != nil {
+			return err
+		}
+		if rf.fsWatcher == nil {
+			rf.watchForExternalRotation()
+		}
+	}
+
 	return nil
 }
 
+// out returns the io.Writer a write should go to right now: the async
+// pipeline when WithAsyncBuffer is set, or the current file directly.
+func (rf *rollingFile) out() io.Writer {
+	if rf.asyncWriter != nil {
+		return rf.asyncWriter
+	}
+	return rf.currentFile
+}
+
+// Writer returns the stable io.Writer callers should point logger.Out at
+// via logger.SetOutput(hook.Writer()), once, right after constructing the
+// hook. It is created once and reused for the lifetime of the hook: unlike
+// the current file or async pipeline underneath it, which change on every
+// roll, this value never does, so it never needs to be reassigned again.
+// Fire deliberately never touches logger.Out itself — see hookWriter.
+func (rf *rollingFile) Writer() *hookWriter {
+	if rf.writer == nil {
+		rf.writer = &hookWriter{rf: rf}
+	}
+	return rf.writer
+}
+
+// Errors returns the channel asynchronous write failures are reported on.
+// It is nil unless WithAsyncBuffer has been used and a file has been
+// opened.
+func (rf *rollingFile) Errors() <-chan error {
+	if rf.asyncWriter == nil {
+		return nil
+	}
+	return rf.asyncWriter.Errors()
+}
+
 
 func (rf *rollingFile) Close() error {
+	rf.rollLock.Lock()
+	defer rf.rollLock.Unlock()
+
+	if rf.activeFilePath != "" {
+		unregisterActiveFile(rf.activeFilePath)
+		rf.activeFilePath = ""
+	}
+
+	if err := rf.stopWatcher(); err != nil {
+		return err
+	}
+	if rf.asyncWriter != nil {
+		if err := rf.asyncWriter.Close(); err != nil {
+			return err
+		}
+		rf.asyncWriter = nil
+	}
 	if rf.currentFile != nil {
 		e := rf.currentFile.Close()
 		if e != nil {
@@ -0,0 +1,77 @@
+package rollingfile
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pruneByAge removes history files under currentDirPath whose ModTime is
+// older than maxAge. It runs independently of, and in addition to, the
+// maxRolls count-based pruning in deleteOldRolls; a no-op when maxAge is
+// not set via WithMaxAge.
+func (rf *rollingFile) pruneByAge() error {
+	if rf.maxAge <= 0 {
+		return nil
+	}
+
+	candidates, err := rf.ageCandidates()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-rf.maxAge)
+	for _, name := range candidates {
+		path := filepath.Join(rf.currentDirPath, name)
+		info, err := os.Lstat(path)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			if err := tryRemoveFile(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ageCandidates returns the file names eligible for age-based pruning.
+// When WithFileNameGlob was supplied, any file in currentDirPath matching
+// that pattern is a candidate, which is what lets several related log
+// families sharing a directory be pruned by one hook. Otherwise it falls
+// back to this hook's own roll-name files.
+//
+// Either way, every hook's active file is excluded, not just this one's: a
+// glob like "*.log" is documented to let several hooks share a directory
+// (e.g. one on "panic.log", another on "audit.log"), and since it also
+// matches each of their live files, pruning by that glob alone would let
+// one hook delete another's active file out from under it. isActiveFile
+// checks the process-wide registry every hook registers itself in for its
+// lifetime (see registerActiveFile), so this exclusion covers other hooks'
+// active files too, not only rf.fileName. That registry, not currentName,
+// is also what protects this hook's own active file before it has one:
+// pruneByAge runs once at construction, before createFileAndFolderIfNeeded
+// has ever set currentName, so comparing against currentName would not yet
+// exclude anything and a pre-existing active file with an old mtime would
+// be deleted before the hook ever opens it. registerActiveFile runs in
+// newRollingFile, before any pruning does.
+func (rf *rollingFile) ageCandidates() ([]string, error) {
+	if rf.fileNameGlob != "" {
+		return getDirFilePaths(rf.currentDirPath, func(name string) bool {
+			absPath, err := filepath.Abs(filepath.Join(rf.currentDirPath, name))
+			if err == nil && isActiveFile(absPath) {
+				return false
+			}
+			matched, _ := filepath.Match(rf.fileNameGlob, name)
+			return matched
+		}, true)
+	}
+	return getDirFilePaths(rf.currentDirPath, func(name string) bool {
+		absPath, err := filepath.Abs(filepath.Join(rf.currentDirPath, name))
+		if err == nil && isActiveFile(absPath) {
+			return false
+		}
+		return name != rf.fileName && rf.hasRollName(name)
+	}, true)
+}
@@ -0,0 +1,110 @@
+package rollingfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestPruneByAgeSkipsActiveFile(t *testing.T) {
+	dir := t.TempDir()
+	fpath := filepath.Join(dir, "app.log")
+
+	hook, err := NewRollingFileTimeHook(fpath, "2006-01-02", 5,
+		WithFileNameGlob("app*.log"), WithMaxAge(time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewRollingFileTimeHook: %s", err)
+	}
+	defer hook.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	logger.AddHook(hook)
+	logger.SetOutput(hook.Writer())
+	logger.Info("hello")
+
+	time.Sleep(5 * time.Millisecond)
+
+	if err := hook.pruneByAge(); err != nil {
+		t.Fatalf("pruneByAge: %s", err)
+	}
+
+	if _, err := os.Stat(fpath); err != nil {
+		t.Fatalf("active file was pruned out from under the hook: %s", err)
+	}
+}
+
+// TestPruneByAgeSkipsActiveFileAtConstruction exercises the pruning
+// NewRollingFileTimeHook itself runs, before any file has ever been
+// opened and currentName has been set. A regression here previously hid
+// behind a currentName check that was always empty at this point, so it
+// never actually excluded the active file.
+func TestPruneByAgeSkipsActiveFileAtConstruction(t *testing.T) {
+	dir := t.TempDir()
+	fpath := filepath.Join(dir, "app.log")
+
+	if err := os.WriteFile(fpath, []byte("existing"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %s", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(fpath, old, old); err != nil {
+		t.Fatalf("Chtimes: %s", err)
+	}
+
+	hook, err := NewRollingFileTimeHook(fpath, "2006-01-02", 5,
+		WithFileNameGlob("app*.log"), WithMaxAge(time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewRollingFileTimeHook: %s", err)
+	}
+	defer hook.Close()
+
+	if _, err := os.Stat(fpath); err != nil {
+		t.Fatalf("active file was pruned out from under the hook at construction: %s", err)
+	}
+}
+
+// TestPruneByAgeSkipsOtherHooksActiveFile exercises the shared-directory
+// use case WithFileNameGlob is documented for: two hooks on different
+// files in the same directory, both matching the same glob. A hook's
+// pruning previously only excluded its own fileName, so it would delete
+// the other hook's active file as soon as that file aged past maxAge.
+func TestPruneByAgeSkipsOtherHooksActiveFile(t *testing.T) {
+	dir := t.TempDir()
+	panicPath := filepath.Join(dir, "panic.log")
+	auditPath := filepath.Join(dir, "audit.log")
+
+	panicHook, err := NewRollingFileTimeHook(panicPath, "2006-01-02", 5,
+		WithFileNameGlob("*.log"), WithMaxAge(time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewRollingFileTimeHook(panic): %s", err)
+	}
+	defer panicHook.Close()
+
+	auditHook, err := NewRollingFileTimeHook(auditPath, "2006-01-02", 5,
+		WithFileNameGlob("*.log"), WithMaxAge(time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewRollingFileTimeHook(audit): %s", err)
+	}
+	defer auditHook.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	logger.AddHook(auditHook)
+	logger.SetOutput(auditHook.Writer())
+	logger.Info("hello")
+
+	// Let audit.log age past maxAge while nothing writes to it again, the
+	// quiet-log case a currentName/fileName-only exclusion would miss.
+	time.Sleep(5 * time.Millisecond)
+
+	if err := panicHook.pruneByAge(); err != nil {
+		t.Fatalf("pruneByAge: %s", err)
+	}
+
+	if _, err := os.Stat(auditPath); err != nil {
+		t.Fatalf("another hook's active file was pruned out from under it: %s", err)
+	}
+}
@@ -0,0 +1,122 @@
+package rollingfile
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RollingFileSizeHook is a logrus hook that rolls the log file once it
+// exceeds a configured size, keeping history files named with a
+// monotonically increasing integer suffix (file.log.1, file.log.2, ...).
+type RollingFileSizeHook struct {
+	*rollingFile
+
+	maxSize int64
+}
+
+// NewRollingFileSizeHook creates a hook that writes to fpath and rolls it
+// to fpath.N (N starting at 1 and increasing) once its size exceeds
+// maxSize, keeping at most maxRolls history files.
+//
+// Fire does not point logger.Out at the hook itself; call
+// logger.SetOutput(hook.Writer()) once, after construction, so logger.Out
+// is only ever assigned outside of concurrent Fire calls (see Writer).
+func NewRollingFileSizeHook(fpath string, maxSize int64, maxRolls int, opts ...Option) (*RollingFileSizeHook, error) {
+	rf, err := newRollingFile(fpath, rollingTypeSize, maxRolls, opts...)
+	if err != nil {
+		return nil, err
+	}
+	rfsh := &RollingFileSizeHook{rf, maxSize}
+	rfsh.self = rfsh
+
+	if err := rfsh.pruneByAge(); err != nil {
+		rfsh.Close()
+		return nil, err
+	}
+
+	return rfsh, nil
+}
+
+func (h *RollingFileSizeHook) Fire(e *logrus.Entry) error {
+	h.rollLock.Lock()
+	defer h.rollLock.Unlock()
+
+	if h.needsToRoll() {
+		if err := h.roll(); err != nil {
+			return err
+		}
+	}
+
+	// first time or rolling file
+	if h.currentFile == nil {
+		err := h.createFileAndFolderIfNeeded()
+		if err != nil {
+			return err
+		}
+	}
+
+	serialized, err := e.Logger.Formatter.Format(e)
+	if err != nil {
+		return err
+	}
+	h.currentFileSize += int64(len(serialized))
+
+	return nil
+}
+
+func (h *RollingFileSizeHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *RollingFileSizeHook) needsToRoll() bool {
+	return h.currentFile != nil && h.currentFileSize > h.maxSize
+}
+
+func (h *RollingFileSizeHook) isFileRollNameValid(rname string) bool {
+	if len(rname) == 0 {
+		return false
+	}
+	_, err := strconv.Atoi(rname)
+	return err == nil
+}
+
+type rollSizeFileTailsSlice []string
+
+func (p rollSizeFileTailsSlice) Len() int {
+	return len(p)
+}
+
+func (p rollSizeFileTailsSlice) Less(i, j int) bool {
+	n1, _ := strconv.Atoi(p[i])
+	n2, _ := strconv.Atoi(p[j])
+	return n1 < n2
+}
+
+func (p rollSizeFileTailsSlice) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
+
+func (h *RollingFileSizeHook) sortFileRollNamesAsc(fs []string) ([]string, error) {
+	ss := rollSizeFileTailsSlice(fs)
+	sort.Sort(ss)
+	return ss, nil
+}
+
+func (h *RollingFileSizeHook) getNewHistoryRollFileName(otherHistoryFiles []string) string {
+	max := 0
+	for _, f := range otherHistoryFiles {
+		rname := h.getFileRollName(f)
+		trimmed := strings.TrimSuffix(rname, compressedFileSuffix)
+		if n, err := strconv.Atoi(trimmed); err == nil && n > max {
+			max = n
+		}
+	}
+	return strconv.Itoa(max + 1)
+}
+
+func (h *RollingFileSizeHook) getCurrentFileName() string {
+	return h.fileName
+}
@@ -0,0 +1,51 @@
+package rollingfile
+
+import "strings"
+
+// strftimeToGoLayout maps the strftime verbs operators most commonly
+// carry over from other log-rotation tooling to their Go reference-time
+// layout equivalent.
+var strftimeToGoLayout = map[string]string{
+	"%Y": "2006",
+	"%y": "06",
+	"%m": "01",
+	"%d": "02",
+	"%H": "15",
+	"%M": "04",
+	"%S": "05",
+	"%j": "002",
+	"%A": "Monday",
+	"%a": "Mon",
+	"%B": "January",
+	"%b": "Jan",
+	"%p": "PM",
+	"%z": "-0700",
+}
+
+// strftimeToLayout translates a C-style strftime pattern into the Go
+// reference-time layout that time.Format/time.ParseInLocation expect.
+// Verbs not in strftimeToGoLayout, and any literal text, are passed
+// through unchanged.
+func strftimeToLayout(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] == '%' && i+1 < len(pattern) {
+			if layout, ok := strftimeToGoLayout[pattern[i:i+2]]; ok {
+				b.WriteString(layout)
+				i++
+				continue
+			}
+		}
+		b.WriteByte(pattern[i])
+	}
+	return b.String()
+}
+
+// NewRollingFileStrftimeHook is like NewRollingFileTimeHook but takes a
+// C-style strftime pattern (e.g. "%Y-%m-%d") instead of a Go layout, so
+// operators can port existing log-rotation configuration unchanged. The
+// pattern is translated to a Go layout once, at construction time; the
+// returned hook is an ordinary RollingFileTimeHook from then on.
+func NewRollingFileStrftimeHook(fpath, strftimePattern string, maxroll int, opts ...Option) (*RollingFileTimeHook, error) {
+	return NewRollingFileTimeHook(fpath, strftimeToLayout(strftimePattern), maxroll, opts...)
+}
@@ -0,0 +1,50 @@
+package rollingfile
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStrftimeToLayout(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    string
+	}{
+		{"%Y-%m-%d", "2006-01-02"},
+		{"%Y-%m-%d_%H-%M-%S", "2006-01-02_15-04-05"},
+		{"%A, %B %d %Y %p %z", "Monday, January 02 2006 PM -0700"},
+		{"%j", "002"},
+	}
+	for _, c := range cases {
+		if got := strftimeToLayout(c.pattern); got != c.want {
+			t.Errorf("strftimeToLayout(%q) = %q, want %q", c.pattern, got, c.want)
+		}
+	}
+}
+
+func TestRollTimeFileTailsSliceRoundTripsStrftime(t *testing.T) {
+	layout := strftimeToLayout("%Y-%m-%d")
+	names := []string{"2023-08-16", "2023-08-14", "2023-08-15"}
+
+	h := &RollingFileTimeHook{timePattern: layout}
+	sorted, err := h.sortFileRollNamesAsc(append([]string{}, names...))
+	if err != nil {
+		t.Fatalf("sortFileRollNamesAsc: %s", err)
+	}
+
+	want := []string{"2023-08-14", "2023-08-15", "2023-08-16"}
+	for i, w := range want {
+		if sorted[i] != w {
+			t.Fatalf("sorted[%d] = %q, want %q", i, sorted[i], w)
+		}
+	}
+
+	for _, n := range names {
+		if !h.isFileRollNameValid(n) {
+			t.Errorf("isFileRollNameValid(%q) = false, want true", n)
+		}
+		if _, err := time.ParseInLocation(layout, n, time.Local); err != nil {
+			t.Errorf("ParseInLocation(%q): %s", n, err)
+		}
+	}
+}
@@ -13,13 +13,26 @@ type RollingFileTimeHook struct {
 	currentTimeFileName string
 }
 
-func NewRollingFileTimeHook(fpath, timePattern string, maxroll int) (*RollingFileTimeHook, error) {
-	rf, err := newRollingFile(fpath, rollingTypeTime, maxroll)
+// NewRollingFileTimeHook creates a hook that writes to fpath and rolls it
+// whenever the current time, formatted with timePattern, changes (e.g.
+// "2006-01-02" rolls once a day), keeping at most maxroll history files.
+//
+// Fire does not point logger.Out at the hook itself; call
+// logger.SetOutput(hook.Writer()) once, after construction, so logger.Out
+// is only ever assigned outside of concurrent Fire calls (see Writer).
+func NewRollingFileTimeHook(fpath, timePattern string, maxroll int, opts ...Option) (*RollingFileTimeHook, error) {
+	rf, err := newRollingFile(fpath, rollingTypeTime, maxroll, opts...)
 	if err != nil {
 		return nil, err
 	}
 	rfth := &RollingFileTimeHook{rf, timePattern, ""}
 	rfth.self = rfth
+
+	if err := rfth.pruneByAge(); err != nil {
+		rfth.Close()
+		return nil, err
+	}
+
 	return rfth, nil
 }
 
@@ -39,7 +52,6 @@ func(h *RollingFileTimeHook) Fire(e *logrus.Entry) error {
 		if err != nil {
 			return err
 		}
-		e.Logger.Out = h.currentFile
 	}
 
 	serialized, err := e.Logger.Formatter.Format(e)
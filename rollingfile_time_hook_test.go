@@ -16,6 +16,7 @@ func TestNewRollingFileTimeHook(t *testing.T) {
 
 	logrus.SetLevel(logrus.DebugLevel)
 	logrus.AddHook(hook)
+	logrus.SetOutput(hook.Writer())
 
 	for i:=0; i < 1000; i++ {
 		logrus.Debugf("TestNewRollingFileTimeHook: %d",i)
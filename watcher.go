@@ -0,0 +1,129 @@
+package rollingfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const symlinkTmpSuffix = ".tmp"
+
+// updateSymlink atomically points currentSymlink at the active log file,
+// by creating a temporary symlink and renaming it into place. A no-op
+// unless WithCurrentSymlink was used.
+func (rf *rollingFile) updateSymlink() error {
+	if rf.currentSymlink == "" {
+		return nil
+	}
+
+	// A symlink target is resolved relative to the symlink's own directory,
+	// not the process CWD, so a target built from currentDirPath (commonly
+	// relative, e.g. "." or "logs") would point at the wrong place as soon
+	// as currentSymlink lives elsewhere, e.g. WithCurrentSymlink("/var/log/app/current.log")
+	// next to log files kept under "logs/". Resolve it to an absolute path
+	// so it resolves the same regardless of where the symlink sits.
+	absTarget, err := filepath.Abs(filepath.Join(rf.currentDirPath, rf.currentName))
+	if err != nil {
+		return err
+	}
+	tmpLink := rf.currentSymlink + symlinkTmpSuffix
+	tryRemoveFile(tmpLink)
+
+	if err := os.Symlink(absTarget, tmpLink); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpLink, rf.currentSymlink); err != nil {
+		tryRemoveFile(tmpLink)
+		return err
+	}
+	return nil
+}
+
+// watchForExternalRotation starts a background goroutine that watches
+// currentDirPath for the active file being renamed or removed out from
+// under the hook (e.g. by an external logrotate) and reopens it, keeping
+// currentFile/currentFileSize consistent under rollLock. It degrades to a
+// no-op, logging to stderr, on platforms fsnotify does not support.
+func (rf *rollingFile) watchForExternalRotation() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logrus rolling file hook internal error: %s\n", err)
+		return
+	}
+
+	if err := watcher.Add(rf.currentDirPath); err != nil {
+		fmt.Fprintf(os.Stderr, "logrus rolling file hook internal error: %s\n", err)
+		watcher.Close()
+		return
+	}
+
+	rf.fsWatcher = watcher
+	go rf.runWatcher(watcher)
+}
+
+func (rf *rollingFile) runWatcher(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			rf.handleExternalEvent(event)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "logrus rolling file hook internal error: %s\n", err)
+		}
+	}
+}
+
+// handleExternalEvent reopens the active file when it was the target of
+// an external rename/remove, so writes keep landing on a live file
+// instead of one that has been unlinked from under it. It does not need
+// to touch e.Logger.Out itself: the caller points that once at the stable
+// hookWriter (see hookwriter.go), which resolves the live file fresh on
+// every write under rollLock.
+func (rf *rollingFile) handleExternalEvent(event fsnotify.Event) {
+	if filepath.Base(event.Name) != rf.currentName {
+		return
+	}
+	if event.Op&(fsnotify.Rename|fsnotify.Remove) == 0 {
+		return
+	}
+
+	rf.rollLock.Lock()
+	defer rf.rollLock.Unlock()
+
+	// Drain and stop the async pipeline before closing the file it writes
+	// to, same as roll() does; createFileAndFolderIfNeeded below
+	// unconditionally builds a new one and would otherwise leak this one's
+	// run() goroutine, with anything still queued in it silently dropped
+	// against the now-closed fd.
+	if rf.asyncWriter != nil {
+		if err := rf.asyncWriter.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "logrus rolling file hook internal error: %s\n", err)
+		}
+		rf.asyncWriter = nil
+	}
+	if rf.currentFile != nil {
+		rf.currentFile.Close()
+		rf.currentFile = nil
+	}
+	if err := rf.createFileAndFolderIfNeeded(); err != nil {
+		fmt.Fprintf(os.Stderr, "logrus rolling file hook internal error: %s\n", err)
+	}
+}
+
+// stopWatcher stops the fsnotify watcher started by watchForExternalRotation,
+// if any.
+func (rf *rollingFile) stopWatcher() error {
+	if rf.fsWatcher == nil {
+		return nil
+	}
+	err := rf.fsWatcher.Close()
+	rf.fsWatcher = nil
+	return err
+}
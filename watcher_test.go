@@ -0,0 +1,133 @@
+package rollingfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+func TestHandleExternalEventRecoversLogging(t *testing.T) {
+	dir := t.TempDir()
+	fpath := filepath.Join(dir, "test_external.log")
+
+	hook, err := NewRollingFileTimeHook(fpath, "2006-01-02", 5)
+	if err != nil {
+		t.Fatalf("NewRollingFileTimeHook: %s", err)
+	}
+	defer hook.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	logger.AddHook(hook)
+	logger.SetOutput(hook.Writer())
+
+	logger.Info("before rotation")
+
+	// Simulate an external logrotate renaming the active file out from
+	// under the hook, then feed the hook the event it would otherwise get
+	// from fsnotify.
+	if err := os.Rename(fpath, fpath+".external"); err != nil {
+		t.Fatalf("os.Rename: %s", err)
+	}
+	hook.handleExternalEvent(fsnotify.Event{Name: fpath, Op: fsnotify.Rename})
+
+	logger.Info("after rotation")
+
+	data, err := os.ReadFile(fpath)
+	if err != nil {
+		t.Fatalf("ReadFile: %s", err)
+	}
+	if !strings.Contains(string(data), "after rotation") {
+		t.Fatalf("new file does not contain the post-rotation line, got: %q", data)
+	}
+}
+
+// TestHandleExternalEventClosesOldAsyncWriter guards against
+// handleExternalEvent recreating the AsynchronousWriter via
+// createFileAndFolderIfNeeded without first closing the one it replaces,
+// which leaks that writer's run() goroutine forever.
+func TestHandleExternalEventClosesOldAsyncWriter(t *testing.T) {
+	dir := t.TempDir()
+	fpath := filepath.Join(dir, "test_external_async.log")
+
+	hook, err := NewRollingFileTimeHook(fpath, "2006-01-02", 5, WithAsyncBuffer(16, DropPolicyBlock))
+	if err != nil {
+		t.Fatalf("NewRollingFileTimeHook: %s", err)
+	}
+	defer hook.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	logger.AddHook(hook)
+	logger.SetOutput(hook.Writer())
+
+	logger.Info("before rotation")
+
+	before := runtime.NumGoroutine()
+
+	if err := os.Rename(fpath, fpath+".external"); err != nil {
+		t.Fatalf("os.Rename: %s", err)
+	}
+	hook.handleExternalEvent(fsnotify.Event{Name: fpath, Op: fsnotify.Rename})
+
+	logger.Info("after rotation")
+
+	// Give the new AsynchronousWriter's run() goroutine a moment to start
+	// and the old one's a moment to have exited, had it leaked.
+	time.Sleep(20 * time.Millisecond)
+
+	after := runtime.NumGoroutine()
+	if after > before {
+		t.Fatalf("goroutine count grew from %d to %d: old AsynchronousWriter was not closed before a new one was created", before, after)
+	}
+}
+
+// TestUpdateSymlinkResolvesAcrossDirectories guards against a symlink whose
+// target was built from currentDirPath verbatim: a symlink target resolves
+// relative to the symlink's own directory, not the process CWD, so it broke
+// as soon as the symlink and the log file lived in different directories
+// (the common case, e.g. a symlink alongside a logs/ subdirectory).
+func TestUpdateSymlinkResolvesAcrossDirectories(t *testing.T) {
+	root := t.TempDir()
+	logDir := filepath.Join(root, "logs")
+	linkDir := filepath.Join(root, "linkdir")
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %s", err)
+	}
+	if err := os.MkdirAll(linkDir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %s", err)
+	}
+
+	fpath := filepath.Join(logDir, "app.log")
+	linkPath := filepath.Join(linkDir, "current.log")
+
+	hook, err := NewRollingFileTimeHook(fpath, "2006-01-02", 5, WithCurrentSymlink(linkPath))
+	if err != nil {
+		t.Fatalf("NewRollingFileTimeHook: %s", err)
+	}
+	defer hook.Close()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.DebugLevel)
+	logger.AddHook(hook)
+	logger.SetOutput(hook.Writer())
+	logger.Info("hello")
+
+	resolved, err := filepath.EvalSymlinks(linkPath)
+	if err != nil {
+		t.Fatalf("EvalSymlinks: %s", err)
+	}
+	wantResolved, err := filepath.EvalSymlinks(fpath)
+	if err != nil {
+		t.Fatalf("EvalSymlinks(fpath): %s", err)
+	}
+	if resolved != wantResolved {
+		t.Fatalf("symlink resolved to %q, want %q", resolved, wantResolved)
+	}
+}